@@ -2,10 +2,13 @@ package rgsw
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 
 	"github.com/tuneinsight/lattigo/v5/core/rlwe"
 	"github.com/tuneinsight/lattigo/v5/utils/buffer"
+	"github.com/tuneinsight/lattigo/v5/utils/lp"
 )
 
 // Ciphertext is a generic type for RGSW ciphertext.
@@ -108,6 +111,140 @@ func (ct *Ciphertext) UnmarshalBinary(p []byte) (err error) {
 	return
 }
 
+// MarshalTagged encodes the ciphertext using the schema-tagged,
+// self-describing wire format (see lattigo/utils/buffer/tagged.go), instead
+// of the fixed positional layout used by WriteTo. parametersHash lets
+// UnmarshalTagged refuse a stream produced for a different RLWE parameter
+// set before attempting to decode the ciphertext; callers typically derive
+// it from the [rlwe.Parameters] the ciphertext was created with.
+//
+// Each of the two [rlwe.GadgetCiphertext] halves is written as an opaque,
+// length-prefixed field, which lets a receiver skip one half and lazily
+// load only the other — e.g. to warm an evaluation-key cache on disk.
+func (ct Ciphertext) MarshalTagged(w buffer.Writer, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = buffer.WriteTaggedHeader(w, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteTaggedHeader: %w", err)
+	}
+	n += inc
+
+	sw := buffer.NewTaggedStructWriter()
+
+	for i := range ct.Value {
+		i := i
+		sw.Field(fmt.Sprintf("Value%d", i), func(w buffer.Writer) (int64, error) {
+			buf := buffer.NewBufferSize(ct.Value[i].BinarySize())
+			if _, err := ct.Value[i].WriteTo(buf); err != nil {
+				return 0, fmt.Errorf("rlwe.GadgetCiphertext.WriteTo: %w", err)
+			}
+			return buffer.WriteTaggedBytes(w, buf.Bytes())
+		})
+	}
+
+	if inc, err = sw.WriteTo(w); err != nil {
+		return n + inc, fmt.Errorf("buffer.TaggedStructWriter.WriteTo: %w", err)
+	}
+	n += inc
+
+	return n, nil
+}
+
+// UnmarshalTagged decodes a ciphertext written by MarshalTagged. Unknown
+// fields on the stream are skipped, so a ciphertext produced by a newer
+// version of this method can still be read by this one.
+func (ct *Ciphertext) UnmarshalTagged(r buffer.Reader, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if _, inc, err = buffer.ReadTaggedHeader(r, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadTaggedHeader: %w", err)
+	}
+	n += inc
+
+	fields := make(map[string]func(r buffer.Reader) (int64, error), len(ct.Value))
+
+	for i := range ct.Value {
+		i := i
+		fields[fmt.Sprintf("Value%d", i)] = func(r buffer.Reader) (int64, error) {
+			data, inc, err := buffer.ReadTaggedBytes(r)
+			if err != nil {
+				return inc, fmt.Errorf("buffer.ReadTaggedBytes: %w", err)
+			}
+			if _, err := ct.Value[i].ReadFrom(buffer.NewBuffer(data)); err != nil {
+				return inc, fmt.Errorf("rlwe.GadgetCiphertext.ReadFrom: %w", err)
+			}
+			return inc, nil
+		}
+	}
+
+	if inc, err = buffer.ReadTaggedStruct(r, fields); err != nil {
+		return n + inc, err
+	}
+	n += inc
+
+	return n, nil
+}
+
+// WriteToLP writes the ciphertext using the length-prefixed recursive
+// encoding (see lattigo/utils/lp), instead of the fixed positional layout
+// used by WriteTo. The two [rlwe.GadgetCiphertext] halves are written as
+// sibling items in a list, so a receiver can [lp.Skip] one of them and
+// lazily load only the other -- e.g. to warm an evaluation-key cache on
+// disk.
+func (ct Ciphertext) WriteToLP(w io.Writer) (n int64, err error) {
+
+	var buf bytes.Buffer
+
+	for i := range ct.Value {
+
+		var half bytes.Buffer
+		if _, err = ct.Value[i].WriteTo(&half); err != nil {
+			return 0, fmt.Errorf("rlwe.GadgetCiphertext.WriteTo: %w", err)
+		}
+
+		if _, err = lp.WriteString(&buf, half.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := lp.WriteList(w, buf.Bytes())
+	return int64(written), err
+}
+
+// ReadFromLP reads a ciphertext written by WriteToLP.
+func (ct *Ciphertext) ReadFromLP(r io.Reader) (n int64, err error) {
+
+	it, headerLen, err := lp.ReadItem(r)
+	if err != nil {
+		return headerLen, err
+	}
+
+	items, err := it.List()
+	if err != nil {
+		return headerLen, err
+	}
+
+	if len(items) != len(ct.Value) {
+		return headerLen, fmt.Errorf("rgsw.Ciphertext.ReadFromLP: got %d halves, want %d", len(items), len(ct.Value))
+	}
+
+	for i, sub := range items {
+
+		data, err := sub.Bytes()
+		if err != nil {
+			return headerLen, err
+		}
+
+		if _, err = ct.Value[i].ReadFrom(bytes.NewReader(data)); err != nil {
+			return headerLen, fmt.Errorf("rlwe.GadgetCiphertext.ReadFrom: %w", err)
+		}
+	}
+
+	return headerLen + it.Len, nil
+}
+
 // Plaintext stores an RGSW plaintext value.
 type Plaintext rlwe.GadgetPlaintext
 
@@ -1,10 +1,13 @@
 package rlwe
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tuneinsight/lattigo/v4/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v4/utils/buffer"
+	"github.com/tuneinsight/lattigo/v4/utils/lp"
 )
 
 // SecretKey is a type for generic RLWE secret keys.
@@ -99,3 +102,96 @@ func (sk *SecretKey) ReadFrom(r io.Reader) (n int64, err error) {
 func (sk *SecretKey) Write(data []byte) (ptr int, err error) {
 	return sk.Value.Write(data)
 }
+
+// MarshalTagged encodes the secret key using the schema-tagged,
+// self-describing wire format (see lattigo/utils/buffer/tagged.go), instead
+// of the fixed positional layout used by WriteTo/Read. parametersHash lets
+// UnmarshalTagged refuse a stream produced for a different RLWE parameter
+// set before attempting to decode the key; callers typically derive it from
+// the Parameters the key was created with.
+//
+// Unlike WriteTo, w must already implement the buffer.Writer interface: it
+// is not wrapped into a bufio.Writer, and it is not flushed.
+func (sk *SecretKey) MarshalTagged(w buffer.Writer, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = buffer.WriteTaggedHeader(w, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteTaggedHeader: %w", err)
+	}
+	n += inc
+
+	sw := buffer.NewTaggedStructWriter()
+
+	sw.Field("Value", func(w buffer.Writer) (int64, error) {
+		data := make([]byte, sk.Value.BinarySize())
+		if _, err := sk.Value.Read(data); err != nil {
+			return 0, fmt.Errorf("ringqp.Poly.Read: %w", err)
+		}
+		return buffer.WriteTaggedBytes(w, data)
+	})
+
+	if inc, err = sw.WriteTo(w); err != nil {
+		return n + inc, fmt.Errorf("buffer.TaggedStructWriter.WriteTo: %w", err)
+	}
+	n += inc
+
+	return n, nil
+}
+
+// UnmarshalTagged decodes a secret key written by MarshalTagged. Unknown
+// fields on the stream are skipped, so a secret key produced by a newer
+// version of this method can still be read by this one.
+func (sk *SecretKey) UnmarshalTagged(r buffer.Reader, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if _, inc, err = buffer.ReadTaggedHeader(r, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadTaggedHeader: %w", err)
+	}
+	n += inc
+
+	inc, err = buffer.ReadTaggedStruct(r, map[string]func(r buffer.Reader) (int64, error){
+		"Value": func(r buffer.Reader) (int64, error) {
+			data, inc, err := buffer.ReadTaggedBytes(r)
+			if err != nil {
+				return inc, fmt.Errorf("buffer.ReadTaggedBytes: %w", err)
+			}
+			if _, err := sk.Value.Write(data); err != nil {
+				return inc, fmt.Errorf("ringqp.Poly.Write: %w", err)
+			}
+			return inc, nil
+		},
+	})
+	n += inc
+
+	return n, err
+}
+
+// WriteToLP writes the secret key using the length-prefixed recursive
+// encoding (see lattigo/utils/lp), instead of the fixed positional layout
+// used by WriteTo/Read.
+func (sk *SecretKey) WriteToLP(w io.Writer) (n int64, err error) {
+	data := make([]byte, sk.Value.BinarySize())
+	if _, err = sk.Value.Read(data); err != nil {
+		return 0, fmt.Errorf("ringqp.Poly.Read: %w", err)
+	}
+	written, err := lp.WriteString(w, data)
+	return int64(written), err
+}
+
+// ReadFromLP reads a secret key written by WriteToLP.
+func (sk *SecretKey) ReadFromLP(r io.Reader) (n int64, err error) {
+	it, headerLen, err := lp.ReadItem(r)
+	if err != nil {
+		return headerLen, err
+	}
+	data, err := it.Bytes()
+	if err != nil {
+		return headerLen, err
+	}
+	if _, err = sk.Value.Write(data); err != nil {
+		return headerLen, fmt.Errorf("ringqp.Poly.Write: %w", err)
+	}
+	return headerLen + it.Len, nil
+}
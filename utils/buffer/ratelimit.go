@@ -0,0 +1,224 @@
+package buffer
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// Strategy governs how many tokens a RateLimitedWriter or RateLimitedReader
+// may spend before it must wait for more to become available.
+type Strategy interface {
+	take(n int64)
+}
+
+// tokenBucket is a classic token-bucket rate limiter: tokens accrue at
+// refillPerSec per second, up to burst, and take blocks until enough tokens
+// are available to cover the request.
+type tokenBucket struct {
+	mu           sync.Mutex
+	tokens       float64
+	burst        float64
+	refillPerSec float64
+	last         time.Time
+}
+
+func newTokenBucket(bytesPerSec, burst int64) *tokenBucket {
+	return &tokenBucket{
+		tokens:       float64(burst),
+		burst:        float64(burst),
+		refillPerSec: float64(bytesPerSec),
+		last:         time.Now(),
+	}
+}
+
+func (b *tokenBucket) take(n int64) {
+
+	remaining := float64(n)
+
+	for remaining > 0 {
+
+		b.mu.Lock()
+
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.refillPerSec
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		spend := remaining
+		if spend > b.tokens {
+			spend = b.tokens
+		}
+		b.tokens -= spend
+		remaining -= spend
+
+		var wait time.Duration
+		if remaining > 0 {
+			wait = time.Duration(remaining / b.refillPerSec * float64(time.Second))
+		}
+
+		b.mu.Unlock()
+
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+}
+
+// LocalStrategy is a Strategy with its own independent token bucket: a
+// RateLimitedWriter or RateLimitedReader built with it is bounded to
+// bytesPerSec regardless of what else is being written or read
+// concurrently.
+type LocalStrategy struct {
+	bucket *tokenBucket
+}
+
+// NewLocalStrategy returns a LocalStrategy with its own bucket of burst
+// tokens, refilled at bytesPerSec tokens per second.
+func NewLocalStrategy(bytesPerSec, burst int64) *LocalStrategy {
+	return &LocalStrategy{bucket: newTokenBucket(bytesPerSec, burst)}
+}
+
+func (s *LocalStrategy) take(n int64) {
+	s.bucket.take(n)
+}
+
+// GlobalStrategy is a Strategy backed by a single token bucket shared by
+// every RateLimitedWriter/RateLimitedReader built from it. Use it to bound
+// the aggregate rate of many concurrent streams, e.g. when serializing
+// many bootstrapping keys concurrently to a network sink.
+type GlobalStrategy struct {
+	bucket *tokenBucket
+}
+
+// NewGlobalStrategy returns a GlobalStrategy backed by one bucket of burst
+// tokens, refilled at bytesPerSec tokens per second and shared by every
+// writer or reader constructed with it.
+func NewGlobalStrategy(bytesPerSec, burst int64) *GlobalStrategy {
+	return &GlobalStrategy{bucket: newTokenBucket(bytesPerSec, burst)}
+}
+
+func (s *GlobalStrategy) take(n int64) {
+	s.bucket.take(n)
+}
+
+// RateLimitedWriter wraps an io.Writer with Strategy-governed throttling. It
+// writes through a bufio.Writer so that it satisfies the Writer interface (Flush,
+// Available, AvailableBuffer) that Matrix[T].WriteTo, SecretKey.WriteTo and
+// rgsw.Ciphertext.WriteTo require to take their fast path instead of
+// allocating their own bufio.Writer around it.
+type RateLimitedWriter struct {
+	bw       *bufio.Writer
+	strategy Strategy
+}
+
+// NewRateLimitedWriter returns a Writer that throttles writes to w to
+// bytesPerSec bytes per second, with bursts of up to burst bytes, using a
+// private LocalStrategy.
+func NewRateLimitedWriter(w io.Writer, bytesPerSec, burst int64) Writer {
+	return NewRateLimitedWriterWithStrategy(w, NewLocalStrategy(bytesPerSec, burst))
+}
+
+// NewRateLimitedWriterWithStrategy returns a Writer that throttles writes to
+// w according to strategy. Pass the same GlobalStrategy to several calls to
+// bound their aggregate rate.
+func NewRateLimitedWriterWithStrategy(w io.Writer, strategy Strategy) Writer {
+	return &RateLimitedWriter{bw: bufio.NewWriter(w), strategy: strategy}
+}
+
+// Write implements io.Writer, blocking until strategy has len(p) tokens
+// available.
+func (rw *RateLimitedWriter) Write(p []byte) (n int, err error) {
+	rw.strategy.take(int64(len(p)))
+	return rw.bw.Write(p)
+}
+
+// WriteByte implements io.ByteWriter, blocking until strategy has one token
+// available.
+func (rw *RateLimitedWriter) WriteByte(c byte) error {
+	rw.strategy.take(1)
+	return rw.bw.WriteByte(c)
+}
+
+// Flush flushes the underlying bufio.Writer to the wrapped io.Writer.
+func (rw *RateLimitedWriter) Flush() error {
+	return rw.bw.Flush()
+}
+
+// Available returns how many bytes can still be written to the underlying
+// bufio.Writer's buffer before a flush.
+func (rw *RateLimitedWriter) Available() int {
+	return rw.bw.Available()
+}
+
+// AvailableBuffer returns an empty buffer with potentially nonzero capacity,
+// for use with append after a call to Available, as bufio.Writer does.
+func (rw *RateLimitedWriter) AvailableBuffer() []byte {
+	return rw.bw.AvailableBuffer()
+}
+
+// RateLimitedReader wraps an io.Reader with Strategy-governed throttling. It
+// reads through a bufio.Reader so that it satisfies the Reader interface (Size,
+// Peek, Discard).
+type RateLimitedReader struct {
+	br       *bufio.Reader
+	strategy Strategy
+}
+
+// NewRateLimitedReader returns a Reader that throttles reads from r to
+// bytesPerSec bytes per second, with bursts of up to burst bytes, using a
+// private LocalStrategy.
+func NewRateLimitedReader(r io.Reader, bytesPerSec, burst int64) Reader {
+	return NewRateLimitedReaderWithStrategy(r, NewLocalStrategy(bytesPerSec, burst))
+}
+
+// NewRateLimitedReaderWithStrategy returns a Reader that throttles reads
+// from r according to strategy. Pass the same GlobalStrategy to several
+// calls to bound their aggregate rate.
+func NewRateLimitedReaderWithStrategy(r io.Reader, strategy Strategy) Reader {
+	return &RateLimitedReader{br: bufio.NewReader(r), strategy: strategy}
+}
+
+// Read implements io.Reader, spending one token per byte actually read
+// before returning.
+func (rr *RateLimitedReader) Read(p []byte) (n int, err error) {
+	n, err = rr.br.Read(p)
+	if n > 0 {
+		rr.strategy.take(int64(n))
+	}
+	return n, err
+}
+
+// ReadByte implements io.ByteReader, blocking until strategy has one token
+// available.
+func (rr *RateLimitedReader) ReadByte() (byte, error) {
+	c, err := rr.br.ReadByte()
+	if err == nil {
+		rr.strategy.take(1)
+	}
+	return c, err
+}
+
+// Size returns the size of the underlying bufio.Reader's buffer in bytes.
+func (rr *RateLimitedReader) Size() int {
+	return rr.br.Size()
+}
+
+// Peek returns the next n bytes without advancing the reader. It spends no
+// tokens: peeked bytes are charged when Read, ReadByte or Discard consumes
+// them.
+func (rr *RateLimitedReader) Peek(n int) ([]byte, error) {
+	return rr.br.Peek(n)
+}
+
+// Discard skips the next n bytes, throttling as if they had been read.
+func (rr *RateLimitedReader) Discard(n int) (discarded int, err error) {
+	discarded, err = rr.br.Discard(n)
+	if discarded > 0 {
+		rr.strategy.take(int64(discarded))
+	}
+	return discarded, err
+}
@@ -0,0 +1,57 @@
+package buffer_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tuneinsight/lattigo/v4/utils/buffer"
+	"github.com/tuneinsight/lattigo/v4/utils/structs"
+)
+
+// TestRateLimitedWriterThroughput checks that a RateLimitedWriter keeps a
+// sustained, ~100MB write within 20% of its configured rate, exercising the
+// steady-state refill path of tokenBucket.take beyond the initial
+// burst-sized freebie. The tolerance is generous because the measurement is
+// real wall-clock time across thousands of separate Write calls (at least
+// two for each of the 1250 rows), each taking the bucket's mutex, and is sensitive to scheduler jitter on a loaded machine.
+func TestRateLimitedWriterThroughput(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping throughput test in short mode")
+	}
+
+	const (
+		rows        = 1250
+		cols        = 10000
+		bytesPerSec = 50_000_000
+		burst       = 1 << 20
+	)
+
+	m := make(structs.Matrix[uint64], rows)
+	for i := range m {
+		m[i] = make([]uint64, cols)
+	}
+
+	size := int64(m.BinarySize())
+
+	rw := buffer.NewRateLimitedWriter(io.Discard, bytesPerSec, burst)
+
+	start := time.Now()
+	n, err := m.WriteTo(rw)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Matrix.WriteTo: %v", err)
+	}
+	if n != size {
+		t.Fatalf("wrote %d bytes, want %d", n, size)
+	}
+
+	want := time.Duration(float64(size) / float64(bytesPerSec) * float64(time.Second))
+	tolerance := time.Duration(0.2 * float64(want))
+
+	if diff := elapsed - want; diff < -tolerance || diff > tolerance {
+		t.Fatalf("throughput out of tolerance: took %s, want %s ± %s", elapsed, want, tolerance)
+	}
+}
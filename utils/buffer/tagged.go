@@ -0,0 +1,738 @@
+package buffer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+)
+
+// Tag identifies the kind of the value that follows it in a tagged stream
+// produced by MarshalTagged. Every value in the stream is preceded by
+// exactly one Tag, which lets a decoder skip a value it does not know how
+// to interpret without aborting the whole stream. The Tag is written with
+// WriteAsUint64, so it occupies 8 bytes on the stream, not a single byte.
+type Tag uint8
+
+const (
+	TagInt8 Tag = iota
+	TagInt16
+	TagInt32
+	TagInt64
+	TagUint8
+	TagUint16
+	TagUint32
+	TagUint64
+	TagFloat32
+	TagFloat64
+	// TagBytes is a length-prefixed (as uint64) byte string. It is used
+	// both for raw byte payloads and as the fallback encoding for values
+	// that only implement the legacy io.WriterTo/BinarySize pair.
+	TagBytes
+	// TagFixedArray is a count-prefixed array whose elements all share the
+	// element Tag written immediately after TagFixedArray, e.g. a row of a
+	// Matrix[uint64].
+	TagFixedArray
+	// TagVariableArray is a count-prefixed array whose elements are each
+	// individually tagged, e.g. a Matrix[T] where T is a struct.
+	TagVariableArray
+	// TagStruct is a field-count-prefixed map of fieldNameHash -> tagged
+	// value. Decoders must skip any fieldNameHash they do not recognize.
+	TagStruct
+)
+
+// Magic identifies the start of a lattigo tagged stream.
+const Magic uint32 = 0x4c415454 // "LATT"
+
+// TaggedMajorVersion and TaggedMinorVersion are the version of the tagged
+// wire format implemented by this file. A decoder refuses to read a stream
+// whose MajorVersion does not match.
+const (
+	TaggedMajorVersion uint8 = 1
+	TaggedMinorVersion uint8 = 0
+)
+
+// TaggedHeader is written at the start of every tagged stream, ahead of the
+// self-describing body, so that a decoder can refuse a stream produced by
+// an incompatible format version or for a different RLWE parameter set
+// before it attempts to decode the body.
+type TaggedHeader struct {
+	Magic          uint32
+	MajorVersion   uint8
+	MinorVersion   uint8
+	ParametersHash uint64
+}
+
+// WriteTaggedHeader writes a TaggedHeader for the given parametersHash on w.
+func WriteTaggedHeader(w Writer, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = WriteAsUint64[uint32](w, Magic); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint32]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[uint8](w, TaggedMajorVersion); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[uint8](w, TaggedMinorVersion); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[uint64](w, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint64]: %w", err)
+	}
+	n += inc
+
+	return n, nil
+}
+
+// ReadTaggedHeader reads a TaggedHeader from r and checks that its Magic,
+// MajorVersion and ParametersHash match what the caller expects. A
+// mismatching MinorVersion is tolerated, since the format is meant to be
+// forward-compatible.
+func ReadTaggedHeader(r Reader, wantParametersHash uint64) (h TaggedHeader, n int64, err error) {
+
+	var inc int64
+
+	if inc, err = ReadAsUint64[uint32](r, &h.Magic); err != nil {
+		return h, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint32]: %w", err)
+	}
+	n += inc
+
+	if h.Magic != Magic {
+		return h, n, fmt.Errorf("invalid tagged stream: got magic 0x%x, want 0x%x", h.Magic, Magic)
+	}
+
+	if inc, err = ReadAsUint64[uint8](r, &h.MajorVersion); err != nil {
+		return h, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if h.MajorVersion != TaggedMajorVersion {
+		return h, n, fmt.Errorf("incompatible tagged stream version: got major %d, want %d", h.MajorVersion, TaggedMajorVersion)
+	}
+
+	if inc, err = ReadAsUint64[uint8](r, &h.MinorVersion); err != nil {
+		return h, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = ReadAsUint64[uint64](r, &h.ParametersHash); err != nil {
+		return h, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint64]: %w", err)
+	}
+	n += inc
+
+	if h.ParametersHash != wantParametersHash {
+		return h, n, fmt.Errorf("parameters mismatch: stream was written for parametersHash 0x%x, want 0x%x", h.ParametersHash, wantParametersHash)
+	}
+
+	return h, n, nil
+}
+
+// TaggedMarshaler is implemented by types that can participate, recursively,
+// in the self-describing tagged wire format defined in this file. Unlike
+// io.WriterTo/io.ReaderFrom, implementations tag every value they write so
+// that an older or newer reader can skip values it does not recognize
+// instead of failing to parse the rest of the stream.
+type TaggedMarshaler interface {
+	MarshalTagged(w Writer) (int64, error)
+	UnmarshalTagged(r Reader) (int64, error)
+}
+
+// FieldHash returns the stable hash used to identify a struct field name in
+// a TagStruct value.
+func FieldHash(name string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum32()
+}
+
+// WriteTaggedBytes writes p as a TagBytes value: tag, length, payload.
+func WriteTaggedBytes(w Writer, p []byte) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = WriteAsUint64[uint8](w, uint8(TagBytes)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[int](w, len(p)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int]: %w", err)
+	}
+	n += inc
+
+	written, err := w.Write(p)
+	n += int64(written)
+	if err != nil {
+		return n, fmt.Errorf("buffer.Writer.Write: %w", err)
+	}
+
+	return n, nil
+}
+
+// ReadTaggedBytes reads a TagBytes value written by WriteTaggedBytes.
+func ReadTaggedBytes(r Reader) (p []byte, n int64, err error) {
+
+	var inc int64
+
+	var tag uint8
+	if inc, err = ReadAsUint64[uint8](r, &tag); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if Tag(tag) != TagBytes {
+		return nil, n, fmt.Errorf("unexpected tag: got %d, want TagBytes (%d)", tag, TagBytes)
+	}
+
+	var size int
+	if inc, err = ReadAsUint64[int](r, &size); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[int]: %w", err)
+	}
+	n += inc
+
+	p = make([]byte, size)
+	read, err := io.ReadFull(r, p)
+	n += int64(read)
+	if err != nil {
+		return nil, n, fmt.Errorf("io.ReadFull: %w", err)
+	}
+
+	return p, n, nil
+}
+
+// scalarTag returns the Tag for the dynamic type of v, and an error if v is
+// not one of the scalar kinds listed in Tag.
+func scalarTag(v any) (Tag, error) {
+	switch v.(type) {
+	case int8:
+		return TagInt8, nil
+	case int16:
+		return TagInt16, nil
+	case int32:
+		return TagInt32, nil
+	case int64, int:
+		return TagInt64, nil
+	case uint8:
+		return TagUint8, nil
+	case uint16:
+		return TagUint16, nil
+	case uint32:
+		return TagUint32, nil
+	case uint64, uint:
+		return TagUint64, nil
+	case float32:
+		return TagFloat32, nil
+	case float64:
+		return TagFloat64, nil
+	default:
+		return 0, fmt.Errorf("unsupported scalar type %T", v)
+	}
+}
+
+// fixedWidth returns the encoded payload width, in bytes, of a scalar Tag.
+// It returns an error for tags that do not have a fixed width.
+func fixedWidth(tag Tag) (int64, error) {
+	switch tag {
+	case TagInt8, TagUint8:
+		return 1, nil
+	case TagInt16, TagUint16:
+		return 2, nil
+	case TagInt32, TagUint32, TagFloat32:
+		return 4, nil
+	case TagInt64, TagUint64, TagFloat64:
+		return 8, nil
+	default:
+		return 0, fmt.Errorf("tag %d does not have a fixed width", tag)
+	}
+}
+
+func writeScalarPayload(w io.Writer, v any) (n int64, err error) {
+
+	var width int64
+
+	switch x := v.(type) {
+	case int8:
+		width, err = 1, binary.Write(w, binary.LittleEndian, x)
+	case int16:
+		width, err = 2, binary.Write(w, binary.LittleEndian, x)
+	case int32:
+		width, err = 4, binary.Write(w, binary.LittleEndian, x)
+	case int64:
+		width, err = 8, binary.Write(w, binary.LittleEndian, x)
+	case int:
+		width, err = 8, binary.Write(w, binary.LittleEndian, int64(x))
+	case uint8:
+		width, err = 1, binary.Write(w, binary.LittleEndian, x)
+	case uint16:
+		width, err = 2, binary.Write(w, binary.LittleEndian, x)
+	case uint32:
+		width, err = 4, binary.Write(w, binary.LittleEndian, x)
+	case uint64:
+		width, err = 8, binary.Write(w, binary.LittleEndian, x)
+	case uint:
+		width, err = 8, binary.Write(w, binary.LittleEndian, uint64(x))
+	case float32:
+		width, err = 4, binary.Write(w, binary.LittleEndian, x)
+	case float64:
+		width, err = 8, binary.Write(w, binary.LittleEndian, x)
+	default:
+		return 0, fmt.Errorf("unsupported scalar type %T", v)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return width, nil
+}
+
+func readScalarPayload(r io.Reader, v any) (n int64, err error) {
+
+	var width int64
+
+	switch p := v.(type) {
+	case *int8:
+		width, err = 1, binary.Read(r, binary.LittleEndian, p)
+	case *int16:
+		width, err = 2, binary.Read(r, binary.LittleEndian, p)
+	case *int32:
+		width, err = 4, binary.Read(r, binary.LittleEndian, p)
+	case *int64:
+		width, err = 8, binary.Read(r, binary.LittleEndian, p)
+	case *int:
+		var tmp int64
+		width, err = 8, binary.Read(r, binary.LittleEndian, &tmp)
+		*p = int(tmp)
+	case *uint8:
+		width, err = 1, binary.Read(r, binary.LittleEndian, p)
+	case *uint16:
+		width, err = 2, binary.Read(r, binary.LittleEndian, p)
+	case *uint32:
+		width, err = 4, binary.Read(r, binary.LittleEndian, p)
+	case *uint64:
+		width, err = 8, binary.Read(r, binary.LittleEndian, p)
+	case *uint:
+		var tmp uint64
+		width, err = 8, binary.Read(r, binary.LittleEndian, &tmp)
+		*p = uint(tmp)
+	case *float32:
+		width, err = 4, binary.Read(r, binary.LittleEndian, p)
+	case *float64:
+		width, err = 8, binary.Read(r, binary.LittleEndian, p)
+	default:
+		return 0, fmt.Errorf("unsupported scalar type %T", v)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return width, nil
+}
+
+// WriteTaggedScalar writes v tagged with its kind, e.g. TagUint64 for a
+// uint64. T must be one of the scalar kinds listed in Tag.
+func WriteTaggedScalar[T any](w Writer, v T) (n int64, err error) {
+
+	tag, err := scalarTag(any(v))
+	if err != nil {
+		return 0, err
+	}
+
+	var inc int64
+	if inc, err = WriteAsUint64[uint8](w, uint8(tag)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	inc, err = writeScalarPayload(w, v)
+	n += inc
+
+	return n, err
+}
+
+// ReadTaggedScalar reads a scalar value written by WriteTaggedScalar into v.
+func ReadTaggedScalar[T any](r Reader, v *T) (n int64, err error) {
+
+	var rawTag uint8
+	var inc int64
+	if inc, err = ReadAsUint64[uint8](r, &rawTag); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	inc, err = readScalarPayload(r, v)
+	n += inc
+
+	return n, err
+}
+
+// WriteTaggedFixedArray writes s as a TagFixedArray value: tag, element
+// tag, count, then each element's raw payload with no per-element tag. T
+// must be one of the scalar kinds listed in Tag.
+func WriteTaggedFixedArray[T any](w Writer, s []T) (n int64, err error) {
+
+	var elem T
+	elemTag, err := scalarTag(any(elem))
+	if err != nil {
+		return 0, err
+	}
+
+	var inc int64
+
+	if inc, err = WriteAsUint64[uint8](w, uint8(TagFixedArray)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[uint8](w, uint8(elemTag)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[int64](w, int64(len(s))); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for _, v := range s {
+		written, err := writeScalarPayload(w, v)
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// ReadTaggedFixedArray reads a TagFixedArray value written by
+// WriteTaggedFixedArray into a newly allocated slice.
+func ReadTaggedFixedArray[T any](r Reader) (s []T, n int64, err error) {
+
+	var rawTag uint8
+	var inc int64
+	if inc, err = ReadAsUint64[uint8](r, &rawTag); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if Tag(rawTag) != TagFixedArray {
+		return nil, n, fmt.Errorf("unexpected tag: got %d, want TagFixedArray (%d)", rawTag, TagFixedArray)
+	}
+
+	var rawElemTag uint8
+	if inc, err = ReadAsUint64[uint8](r, &rawElemTag); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	var count int64
+	if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	s = make([]T, count)
+	for i := range s {
+		read, err := readScalarPayload(r, &s[i])
+		n += read
+		if err != nil {
+			return nil, n, err
+		}
+	}
+
+	return s, n, nil
+}
+
+// WriteTaggedVariableArray writes each element of s, individually tagged
+// via its TaggedMarshaler.MarshalTagged, framed as a TagVariableArray
+// value.
+func WriteTaggedVariableArray[T TaggedMarshaler](w Writer, s []T) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = WriteAsUint64[uint8](w, uint8(TagVariableArray)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[int64](w, int64(len(s))); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for _, v := range s {
+		if inc, err = v.MarshalTagged(w); err != nil {
+			return n + inc, err
+		}
+		n += inc
+	}
+
+	return n, nil
+}
+
+// ReadTaggedVariableArray reads a TagVariableArray value written by
+// WriteTaggedVariableArray, allocating each element with newT.
+func ReadTaggedVariableArray[T TaggedMarshaler](r Reader, newT func() T) (s []T, n int64, err error) {
+
+	var rawTag uint8
+	var inc int64
+	if inc, err = ReadAsUint64[uint8](r, &rawTag); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if Tag(rawTag) != TagVariableArray {
+		return nil, n, fmt.Errorf("unexpected tag: got %d, want TagVariableArray (%d)", rawTag, TagVariableArray)
+	}
+
+	var count int64
+	if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+		return nil, n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	s = make([]T, count)
+	for i := range s {
+		s[i] = newT()
+		if inc, err = s[i].UnmarshalTagged(r); err != nil {
+			return nil, n + inc, err
+		}
+		n += inc
+	}
+
+	return s, n, nil
+}
+
+// TaggedStructWriter accumulates named fields and writes them framed as a
+// single TagStruct value. It is the building block used by types whose
+// MarshalTagged emits a fixed set of named fields, e.g. SecretKey{Value}.
+type TaggedStructWriter struct {
+	fields []taggedField
+}
+
+type taggedField struct {
+	name string
+	enc  func(w Writer) (int64, error)
+}
+
+// NewTaggedStructWriter returns an empty TaggedStructWriter.
+func NewTaggedStructWriter() *TaggedStructWriter {
+	return &TaggedStructWriter{}
+}
+
+// Field registers a named field to be written by WriteTo, keyed on the
+// stream by FieldHash(name).
+func (sw *TaggedStructWriter) Field(name string, enc func(w Writer) (int64, error)) {
+	sw.fields = append(sw.fields, taggedField{name, enc})
+}
+
+// WriteTo writes the accumulated fields as a single TagStruct value on w.
+func (sw *TaggedStructWriter) WriteTo(w Writer) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = WriteAsUint64[uint8](w, uint8(TagStruct)); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if inc, err = WriteAsUint64[int64](w, int64(len(sw.fields))); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for _, f := range sw.fields {
+
+		if inc, err = WriteAsUint64[uint32](w, FieldHash(f.name)); err != nil {
+			return n + inc, fmt.Errorf("buffer.WriteAsUint64[uint32]: %w", err)
+		}
+		n += inc
+
+		if inc, err = f.enc(w); err != nil {
+			return n + inc, err
+		}
+		n += inc
+	}
+
+	return n, nil
+}
+
+// ReadTaggedStruct reads a TagStruct value from r and dispatches each
+// encoded field to the matching decoder in fields, keyed by field name.
+// Field hashes found on the stream but absent from fields are skipped via
+// SkipTagged, which is what lets an older reader load a stream written by a
+// newer writer that added fields (and vice-versa).
+func ReadTaggedStruct(r Reader, fields map[string]func(r Reader) (int64, error)) (n int64, err error) {
+
+	var inc int64
+
+	var rawTag uint8
+	if inc, err = ReadAsUint64[uint8](r, &rawTag); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	if Tag(rawTag) != TagStruct {
+		return n, fmt.Errorf("unexpected tag: got %d, want TagStruct (%d)", rawTag, TagStruct)
+	}
+
+	byHash := make(map[uint32]func(r Reader) (int64, error), len(fields))
+	for name, dec := range fields {
+		byHash[FieldHash(name)] = dec
+	}
+
+	var count int64
+	if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for i := int64(0); i < count; i++ {
+
+		var fieldHash uint32
+		if inc, err = ReadAsUint64[uint32](r, &fieldHash); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint32]: %w", err)
+		}
+		n += inc
+
+		dec, known := byHash[fieldHash]
+		if !known {
+			if inc, err = SkipTagged(r); err != nil {
+				return n + inc, err
+			}
+			n += inc
+			continue
+		}
+
+		if inc, err = dec(r); err != nil {
+			return n + inc, err
+		}
+		n += inc
+	}
+
+	return n, nil
+}
+
+// SkipTagged reads the next tagged value from r and discards its payload,
+// without the caller needing to know its kind. This is what allows an
+// older or newer reader to skip an unknown TagStruct field, or an entire
+// unknown top-level value, and keep parsing the rest of the stream.
+func SkipTagged(r Reader) (n int64, err error) {
+
+	var inc int64
+
+	var rawTag uint8
+	if inc, err = ReadAsUint64[uint8](r, &rawTag); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+	}
+	n += inc
+
+	tag := Tag(rawTag)
+
+	switch tag {
+	case TagBytes:
+
+		var size int64
+		if inc, err = ReadAsUint64[int64](r, &size); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+		}
+		n += inc
+
+		skipped, err := skipN(r, size)
+		return n + skipped, err
+
+	case TagFixedArray:
+
+		var rawElemTag uint8
+		if inc, err = ReadAsUint64[uint8](r, &rawElemTag); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint8]: %w", err)
+		}
+		n += inc
+
+		width, err := fixedWidth(Tag(rawElemTag))
+		if err != nil {
+			return n, err
+		}
+
+		var count int64
+		if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+		}
+		n += inc
+
+		skipped, err := skipN(r, count*width)
+		return n + skipped, err
+
+	case TagVariableArray:
+
+		var count int64
+		if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+		}
+		n += inc
+
+		for i := int64(0); i < count; i++ {
+			if inc, err = SkipTagged(r); err != nil {
+				return n + inc, err
+			}
+			n += inc
+		}
+
+		return n, nil
+
+	case TagStruct:
+
+		var count int64
+		if inc, err = ReadAsUint64[int64](r, &count); err != nil {
+			return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+		}
+		n += inc
+
+		for i := int64(0); i < count; i++ {
+
+			var fieldHash uint32
+			if inc, err = ReadAsUint64[uint32](r, &fieldHash); err != nil {
+				return n + inc, fmt.Errorf("buffer.ReadAsUint64[uint32]: %w", err)
+			}
+			n += inc
+
+			if inc, err = SkipTagged(r); err != nil {
+				return n + inc, err
+			}
+			n += inc
+		}
+
+		return n, nil
+
+	default:
+
+		width, err := fixedWidth(tag)
+		if err != nil {
+			return n, fmt.Errorf("unknown tag %d: %w", rawTag, err)
+		}
+
+		skipped, err := skipN(r, width)
+		return n + skipped, err
+	}
+}
+
+// skipN discards the next size bytes from r, returning an error if fewer
+// than size bytes could be read -- a truncated stream must not be treated
+// as a successful skip, since the caller's byte-accounting would then be
+// wrong for everything that follows.
+func skipN(r io.Reader, size int64) (int64, error) {
+	copied, err := io.CopyN(io.Discard, r, size)
+	if err != nil {
+		return copied, fmt.Errorf("io.CopyN: %w", err)
+	}
+	return copied, nil
+}
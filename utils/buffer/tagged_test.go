@@ -0,0 +1,169 @@
+package buffer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v4/utils/buffer"
+)
+
+func TestWriteReadTaggedScalar(t *testing.T) {
+
+	w := buffer.NewBufferSize(16)
+
+	n, err := buffer.WriteTaggedScalar(w, uint64(0xdeadbeef))
+	if err != nil {
+		t.Fatalf("WriteTaggedScalar: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n])
+
+	var got uint64
+	if _, err := buffer.ReadTaggedScalar(r, &got); err != nil {
+		t.Fatalf("ReadTaggedScalar: %v", err)
+	}
+
+	if got != 0xdeadbeef {
+		t.Fatalf("got %#x, want %#x", got, 0xdeadbeef)
+	}
+}
+
+func TestWriteReadTaggedBytes(t *testing.T) {
+
+	want := bytes.Repeat([]byte{0x42}, 200)
+
+	w := buffer.NewBufferSize(len(want) + 16)
+
+	n, err := buffer.WriteTaggedBytes(w, want)
+	if err != nil {
+		t.Fatalf("WriteTaggedBytes: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n])
+
+	got, _, err := buffer.ReadTaggedBytes(r)
+	if err != nil {
+		t.Fatalf("ReadTaggedBytes: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %d bytes, want %d bytes", len(got), len(want))
+	}
+}
+
+func TestWriteReadTaggedFixedArray(t *testing.T) {
+
+	want := []uint64{1, 2, 3, 1 << 40}
+
+	w := buffer.NewBufferSize(8*len(want) + 32)
+
+	n, err := buffer.WriteTaggedFixedArray(w, want)
+	if err != nil {
+		t.Fatalf("WriteTaggedFixedArray: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n])
+
+	got, _, err := buffer.ReadTaggedFixedArray[uint64](r)
+	if err != nil {
+		t.Fatalf("ReadTaggedFixedArray: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d elements, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("element %d: got %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+// TestTaggedStructUnknownFieldSkip writes a struct with an extra field that
+// the reader does not know about, simulating a stream produced by a newer
+// writer. It must be skipped via SkipTagged (through ReadTaggedStruct)
+// without breaking the decode of the fields the reader does know.
+func TestTaggedStructUnknownFieldSkip(t *testing.T) {
+
+	w := buffer.NewBufferSize(256)
+
+	sw := buffer.NewTaggedStructWriter()
+	sw.Field("A", func(w buffer.Writer) (int64, error) {
+		return buffer.WriteTaggedScalar(w, int64(1))
+	})
+	sw.Field("FromTheFuture", func(w buffer.Writer) (int64, error) {
+		return buffer.WriteTaggedBytes(w, bytes.Repeat([]byte{0xff}, 64))
+	})
+	sw.Field("C", func(w buffer.Writer) (int64, error) {
+		return buffer.WriteTaggedScalar(w, int64(3))
+	})
+
+	n, err := sw.WriteTo(w)
+	if err != nil {
+		t.Fatalf("TaggedStructWriter.WriteTo: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n])
+
+	var a, c int64
+	if _, err := buffer.ReadTaggedStruct(r, map[string]func(r buffer.Reader) (int64, error){
+		"A": func(r buffer.Reader) (int64, error) { return buffer.ReadTaggedScalar(r, &a) },
+		"C": func(r buffer.Reader) (int64, error) { return buffer.ReadTaggedScalar(r, &c) },
+	}); err != nil {
+		t.Fatalf("ReadTaggedStruct: %v", err)
+	}
+
+	if a != 1 || c != 3 {
+		t.Fatalf("got A=%d C=%d, want A=1 C=3", a, c)
+	}
+}
+
+// TestSkipTaggedThenReadNext checks that SkipTagged consumes exactly one
+// value, leaving the stream correctly positioned to decode the next one.
+func TestSkipTaggedThenReadNext(t *testing.T) {
+
+	w := buffer.NewBufferSize(128)
+
+	n1, err := buffer.WriteTaggedFixedArray(w, []uint64{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("WriteTaggedFixedArray: %v", err)
+	}
+	n2, err := buffer.WriteTaggedScalar(w, int32(-7))
+	if err != nil {
+		t.Fatalf("WriteTaggedScalar: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n1+n2])
+
+	if _, err := buffer.SkipTagged(r); err != nil {
+		t.Fatalf("SkipTagged: %v", err)
+	}
+
+	var got int32
+	if _, err := buffer.ReadTaggedScalar(r, &got); err != nil {
+		t.Fatalf("ReadTaggedScalar: %v", err)
+	}
+
+	if got != -7 {
+		t.Fatalf("got %d, want -7", got)
+	}
+}
+
+// TestSkipTaggedTruncatedStream checks that a truncated stream surfaces an
+// error from SkipTagged instead of being silently treated as skipped.
+func TestSkipTaggedTruncatedStream(t *testing.T) {
+
+	w := buffer.NewBufferSize(128)
+
+	n, err := buffer.WriteTaggedBytes(w, bytes.Repeat([]byte{0x01}, 64))
+	if err != nil {
+		t.Fatalf("WriteTaggedBytes: %v", err)
+	}
+
+	r := buffer.NewBuffer(w.Bytes()[:n-10])
+
+	if _, err := buffer.SkipTagged(r); err == nil {
+		t.Fatalf("SkipTagged on a truncated stream: got nil error, want non-nil")
+	}
+}
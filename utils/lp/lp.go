@@ -0,0 +1,525 @@
+// Package lp implements a minimalist, RLP-inspired, length-prefixed
+// recursive encoding: every value is either a byte string or a list of
+// sub-items, each preceded by a length header. Because the header alone
+// carries the length of its payload, a reader can [Skip] any subtree by
+// decoding only its header; the payload bytes that follow are still read
+// off the stream and discarded, but not decoded -- which
+// allows, for example, loading a single row of a [lattigo/utils/structs.Matrix]
+// or a single [rlwe.GadgetCiphertext] half of an RGSW ciphertext without
+// deserializing the rest of the stream.
+package lp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// The first byte of every item falls in one of four ranges:
+//
+//   - [0x00, 0x7f]: a literal byte, which is its own value.
+//   - [0x80, 0xb7]: a short string, b-0x80 bytes long.
+//   - [0xb8, 0xbf]: a long string, whose length occupies the next b-0xb7
+//     bytes (big-endian, no leading zero byte), followed by the string.
+//   - [0xc0, 0xf7] and [0xf8, 0xff]: the mirror set for lists, encoding the
+//     total length of the (already length-prefixed) sub-items instead of a
+//     raw byte count.
+const (
+	offsetShortString = 0x80
+	offsetLongString  = 0xb7
+	offsetShortList   = 0xc0
+	offsetLongList    = 0xf7
+)
+
+// WriteString writes p as a single length-prefixed byte string item.
+func WriteString(w io.Writer, p []byte) (n int, err error) {
+	switch {
+	case len(p) == 1 && p[0] < offsetShortString:
+		return w.Write(p)
+
+	case len(p) < 56:
+		if _, err = w.Write([]byte{offsetShortString + byte(len(p))}); err != nil {
+			return 0, err
+		}
+		n, err = w.Write(p)
+		return n + 1, err
+
+	default:
+		lenBytes := minimalBigEndian(uint64(len(p)))
+		if _, err = w.Write([]byte{offsetLongString + byte(len(lenBytes))}); err != nil {
+			return 0, err
+		}
+		if _, err = w.Write(lenBytes); err != nil {
+			return 0, err
+		}
+		n, err = w.Write(p)
+		return n + 1 + len(lenBytes), err
+	}
+}
+
+// WriteList writes payload, which must already be a concatenation of
+// complete sub-items, as a single length-prefixed list item.
+func WriteList(w io.Writer, payload []byte) (n int, err error) {
+	switch {
+	case len(payload) < 56:
+		if _, err = w.Write([]byte{offsetShortList + byte(len(payload))}); err != nil {
+			return 0, err
+		}
+		n, err = w.Write(payload)
+		return n + 1, err
+
+	default:
+		lenBytes := minimalBigEndian(uint64(len(payload)))
+		if _, err = w.Write([]byte{offsetLongList + byte(len(lenBytes))}); err != nil {
+			return 0, err
+		}
+		if _, err = w.Write(lenBytes); err != nil {
+			return 0, err
+		}
+		n, err = w.Write(payload)
+		return n + 1 + len(lenBytes), err
+	}
+}
+
+func minimalBigEndian(v uint64) []byte {
+	if v == 0 {
+		return nil
+	}
+	var tmp [8]byte
+	for i := 7; i >= 0; i-- {
+		tmp[i] = byte(v)
+		v >>= 8
+	}
+	i := 0
+	for i < 7 && tmp[i] == 0 {
+		i++
+	}
+	return tmp[i:]
+}
+
+func bigEndianToUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// Kind distinguishes the two item shapes of the grammar.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindList
+)
+
+// Item is a decoded length header: its Kind and the length, in bytes, of
+// its as-yet-unread payload.
+type Item struct {
+	Kind Kind
+	Len  int64
+	body io.Reader
+}
+
+// ReadItem reads and decodes the length-prefix of the next item on r,
+// returning an Item whose payload has not yet been consumed.
+func ReadItem(r io.Reader) (it Item, headerLen int64, err error) {
+
+	var first [1]byte
+	if _, err = io.ReadFull(r, first[:]); err != nil {
+		return it, 0, err
+	}
+	b := first[0]
+
+	switch {
+	case b < offsetShortString:
+		// A literal byte is its own payload and has no separate header.
+		return Item{Kind: KindString, Len: 1, body: bytes.NewReader(first[:])}, 0, nil
+
+	case b <= offsetLongString:
+		length := int64(b - offsetShortString)
+		return Item{Kind: KindString, Len: length, body: io.LimitReader(r, length)}, 1, nil
+
+	case b <= 0xbf:
+		lenOfLen := int(b - offsetLongString)
+		lenBytes := make([]byte, lenOfLen)
+		if _, err = io.ReadFull(r, lenBytes); err != nil {
+			return it, 0, err
+		}
+		length := int64(bigEndianToUint(lenBytes))
+		return Item{Kind: KindString, Len: length, body: io.LimitReader(r, length)}, int64(1 + lenOfLen), nil
+
+	case b <= offsetLongList:
+		length := int64(b - offsetShortList)
+		return Item{Kind: KindList, Len: length, body: io.LimitReader(r, length)}, 1, nil
+
+	default:
+		lenOfLen := int(b - offsetLongList)
+		lenBytes := make([]byte, lenOfLen)
+		if _, err = io.ReadFull(r, lenBytes); err != nil {
+			return it, 0, err
+		}
+		length := int64(bigEndianToUint(lenBytes))
+		return Item{Kind: KindList, Len: length, body: io.LimitReader(r, length)}, int64(1 + lenOfLen), nil
+	}
+}
+
+// Bytes reads and returns the full payload of the item.
+func (it Item) Bytes() ([]byte, error) {
+	return io.ReadAll(it.body)
+}
+
+// Skip discards the item's payload without decoding it, and returns the
+// number of bytes discarded.
+func (it Item) Skip() (int64, error) {
+	return io.Copy(io.Discard, it.body)
+}
+
+// List decodes the item's payload as a sequence of sub-items. It is an
+// error to call List on a KindString item.
+func (it Item) List() ([]Item, error) {
+	if it.Kind != KindList {
+		return nil, fmt.Errorf("lp: Item.List called on a string item")
+	}
+	data, err := it.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(data)
+	var items []Item
+	for r.Len() > 0 {
+		item, _, err := ReadItem(r)
+		if err != nil {
+			return nil, err
+		}
+		// item.body is an io.LimitReader over r: draining it here, rather than
+		// leaving it for the caller, is what advances r past this item's
+		// payload so the next iteration's ReadItem sees the next header
+		// instead of the middle of this item's bytes.
+		payload, err := io.ReadAll(item.body)
+		if err != nil {
+			return nil, err
+		}
+		item.body = bytes.NewReader(payload)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// raw re-serializes the item's header and payload into a single buffer, so
+// that it can be replayed through a type that parses its own header, such
+// as an LPMarshaler.ReadFromLP.
+func (it Item) raw() ([]byte, error) {
+	payload, err := it.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch it.Kind {
+	case KindString:
+		if _, err := WriteString(&buf, payload); err != nil {
+			return nil, err
+		}
+	case KindList:
+		if _, err := WriteList(&buf, payload); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Skip reads and discards the next item on r, returning the total number of
+// bytes consumed (header and payload).
+func Skip(r io.Reader) (n int64, err error) {
+	it, headerLen, err := ReadItem(r)
+	if err != nil {
+		return headerLen, err
+	}
+	skipped, err := it.Skip()
+	return headerLen + skipped, err
+}
+
+// LPMarshaler is implemented by types that encode and decode themselves
+// using the grammar defined in this package, so that a reflect-based
+// [Encode]/[Decode] call can recurse into a slice of such types without
+// needing to understand their internal layout.
+type LPMarshaler interface {
+	WriteToLP(w io.Writer) (n int64, err error)
+	ReadFromLP(r io.Reader) (n int64, err error)
+}
+
+var lpMarshalerType = reflect.TypeOf((*LPMarshaler)(nil)).Elem()
+
+type planKind int
+
+const (
+	planBytes        planKind = iota // []byte
+	planNumericSlice                 // []T, T a fixed-width number, packed little-endian into one string
+	planMarshalerSlice               // []E or [N]E, E implements LPMarshaler, as a list of sub-items
+	planNestedSlice                  // [][]X, as a list of sub-items, one per inner slice
+)
+
+// plan is the cached, reflection-derived encoding/decoding strategy for a
+// given reflect.Type. Building a plan requires reflection; applying it does
+// not -- which is the point of the typecache below: hot-path (de)serialization
+// of e.g. a Matrix[uint64] pays the cost of reflect.Type inspection once per
+// process, not once per call.
+type plan struct {
+	kind  planKind
+	inner *plan
+	width int
+}
+
+var typecache sync.Map // map[reflect.Type]*plan
+
+func planFor(typ reflect.Type) (*plan, error) {
+	if v, ok := typecache.Load(typ); ok {
+		return v.(*plan), nil
+	}
+	p, err := buildPlan(typ)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := typecache.LoadOrStore(typ, p)
+	return actual.(*plan), nil
+}
+
+func buildPlan(typ reflect.Type) (*plan, error) {
+
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		return nil, fmt.Errorf("lp: unsupported type %s, expected a slice or array", typ)
+	}
+
+	elem := typ.Elem()
+
+	if elem.Kind() == reflect.Uint8 {
+		return &plan{kind: planBytes}, nil
+	}
+
+	if elem.Implements(lpMarshalerType) || reflect.PointerTo(elem).Implements(lpMarshalerType) {
+		return &plan{kind: planMarshalerSlice}, nil
+	}
+
+	if elem.Kind() == reflect.Slice {
+		inner, err := buildPlan(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &plan{kind: planNestedSlice, inner: inner}, nil
+	}
+
+	width, err := numericWidth(elem.Kind())
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan{kind: planNumericSlice, width: width}, nil
+}
+
+func numericWidth(k reflect.Kind) (int, error) {
+	switch k {
+	case reflect.Int8, reflect.Uint8:
+		return 1, nil
+	case reflect.Int16, reflect.Uint16:
+		return 2, nil
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4, nil
+	case reflect.Int64, reflect.Uint64, reflect.Int, reflect.Uint, reflect.Float64:
+		return 8, nil
+	default:
+		return 0, fmt.Errorf("lp: unsupported element kind %s", k)
+	}
+}
+
+func putNumeric(b []byte, v reflect.Value) {
+	var u uint64
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		u = uint64(v.Int())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		u = v.Uint()
+	case reflect.Float32:
+		u = uint64(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		u = math.Float64bits(v.Float())
+	}
+	for i := range b {
+		b[i] = byte(u)
+		u >>= 8
+	}
+}
+
+func setNumeric(v reflect.Value, b []byte) {
+	var u uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		u = u<<8 | uint64(b[i])
+	}
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		v.SetInt(int64(u))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		v.SetUint(u)
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(uint32(u))))
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(u))
+	}
+}
+
+func (p *plan) encode(v reflect.Value, w io.Writer) (int64, error) {
+	switch p.kind {
+	case planBytes:
+		n, err := WriteString(w, v.Bytes())
+		return int64(n), err
+
+	case planNumericSlice:
+		buf := make([]byte, v.Len()*p.width)
+		for i := 0; i < v.Len(); i++ {
+			putNumeric(buf[i*p.width:(i+1)*p.width], v.Index(i))
+		}
+		n, err := WriteString(w, buf)
+		return int64(n), err
+
+	case planMarshalerSlice:
+		var buf bytes.Buffer
+		for i := 0; i < v.Len(); i++ {
+			m, ok := elemMarshaler(v.Index(i))
+			if !ok {
+				return 0, fmt.Errorf("lp: element of type %s does not implement LPMarshaler", v.Index(i).Type())
+			}
+			if _, err := m.WriteToLP(&buf); err != nil {
+				return 0, err
+			}
+		}
+		n, err := WriteList(w, buf.Bytes())
+		return int64(n), err
+
+	case planNestedSlice:
+		var buf bytes.Buffer
+		for i := 0; i < v.Len(); i++ {
+			if _, err := p.inner.encode(v.Index(i), &buf); err != nil {
+				return 0, err
+			}
+		}
+		n, err := WriteList(w, buf.Bytes())
+		return int64(n), err
+
+	default:
+		return 0, fmt.Errorf("lp: unreachable plan kind %d", p.kind)
+	}
+}
+
+func (p *plan) decode(v reflect.Value, it Item) (int64, error) {
+	switch p.kind {
+	case planBytes:
+		data, err := it.Bytes()
+		if err != nil {
+			return 0, err
+		}
+		v.SetBytes(data)
+		return it.Len, nil
+
+	case planNumericSlice:
+		data, err := it.Bytes()
+		if err != nil {
+			return 0, err
+		}
+		count := len(data) / p.width
+		v.Set(reflect.MakeSlice(v.Type(), count, count))
+		for i := 0; i < count; i++ {
+			setNumeric(v.Index(i), data[i*p.width:(i+1)*p.width])
+		}
+		return it.Len, nil
+
+	case planMarshalerSlice:
+		items, err := it.List()
+		if err != nil {
+			return 0, err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		}
+		for i, sub := range items {
+			raw, err := sub.raw()
+			if err != nil {
+				return 0, err
+			}
+			m, ok := elemMarshaler(v.Index(i))
+			if !ok {
+				return 0, fmt.Errorf("lp: element of type %s does not implement LPMarshaler", v.Index(i).Type())
+			}
+			if _, err := m.ReadFromLP(bytes.NewReader(raw)); err != nil {
+				return 0, err
+			}
+		}
+		return it.Len, nil
+
+	case planNestedSlice:
+		items, err := it.List()
+		if err != nil {
+			return 0, err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		for i, sub := range items {
+			if _, err := p.inner.decode(v.Index(i), sub); err != nil {
+				return 0, err
+			}
+		}
+		return it.Len, nil
+
+	default:
+		return 0, fmt.Errorf("lp: unreachable plan kind %d", p.kind)
+	}
+}
+
+func elemMarshaler(v reflect.Value) (LPMarshaler, bool) {
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(LPMarshaler); ok {
+			return m, true
+		}
+	}
+	m, ok := v.Interface().(LPMarshaler)
+	return m, ok
+}
+
+// Encode writes v -- a slice or array such as []uint64, [][]uint64, or a
+// slice/array of a type implementing LPMarshaler -- using the grammar
+// defined in this package, memoizing the reflect-derived encoding plan for
+// reflect.TypeOf(v).
+func Encode(w io.Writer, v any) (n int64, err error) {
+	rv := reflect.ValueOf(v)
+	p, err := planFor(rv.Type())
+	if err != nil {
+		return 0, err
+	}
+	return p.encode(rv, w)
+}
+
+// Decode reads a value written by Encode into v, which must be a pointer to
+// a slice of the same shape that was passed to Encode, or to an array whose
+// elements implement LPMarshaler. Arrays of other element types are not
+// supported.
+func Decode(r io.Reader, v any) (n int64, err error) {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer {
+		return 0, fmt.Errorf("lp: Decode requires a pointer, got %s", rv.Type())
+	}
+	elem := rv.Elem()
+
+	p, err := planFor(elem.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	it, headerLen, err := ReadItem(r)
+	if err != nil {
+		return headerLen, err
+	}
+
+	consumed, err := p.decode(elem, it)
+	return headerLen + consumed, err
+}
@@ -0,0 +1,177 @@
+package lp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v4/utils/lp"
+)
+
+func TestEncodeDecodeNumericSlice(t *testing.T) {
+
+	want := []uint64{1, 2, 3, 1 << 40}
+
+	var buf bytes.Buffer
+	if _, err := lp.Encode(&buf, want); err != nil {
+		t.Fatalf("lp.Encode: %v", err)
+	}
+
+	var got []uint64
+	if _, err := lp.Decode(&buf, &got); err != nil {
+		t.Fatalf("lp.Decode: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d elements, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("element %d: got %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEncodeDecodeNestedSlice(t *testing.T) {
+
+	want := [][]uint64{{1, 2}, {3, 4, 5}, {}}
+
+	var buf bytes.Buffer
+	if _, err := lp.Encode(&buf, want); err != nil {
+		t.Fatalf("lp.Encode: %v", err)
+	}
+
+	var got [][]uint64
+	if _, err := lp.Decode(&buf, &got); err != nil {
+		t.Fatalf("lp.Decode: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("row %d: got %d elements, want %d", i, len(got[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("row %d, element %d: got %d, want %d", i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// TestSkipPartialRow proves the core claim of the package: a receiver can
+// Skip one row of an encoded [][]uint64 and read only the other, without
+// decoding the skipped row's payload.
+func TestSkipPartialRow(t *testing.T) {
+
+	rows := [][]uint64{
+		{1, 2, 3},
+		{4, 5},
+	}
+
+	var buf bytes.Buffer
+	if _, err := lp.Encode(&buf, rows); err != nil {
+		t.Fatalf("lp.Encode: %v", err)
+	}
+
+	outer, _, err := lp.ReadItem(&buf)
+	if err != nil {
+		t.Fatalf("lp.ReadItem (outer): %v", err)
+	}
+
+	items, err := outer.List()
+	if err != nil {
+		t.Fatalf("Item.List: %v", err)
+	}
+	if len(items) != len(rows) {
+		t.Fatalf("got %d rows, want %d", len(items), len(rows))
+	}
+
+	if _, err := items[0].Skip(); err != nil {
+		t.Fatalf("Item.Skip on row 0: %v", err)
+	}
+
+	data, err := items[1].Bytes()
+	if err != nil {
+		t.Fatalf("Item.Bytes on row 1: %v", err)
+	}
+	if len(data) != len(rows[1])*8 {
+		t.Fatalf("row 1 payload: got %d bytes, want %d", len(data), len(rows[1])*8)
+	}
+
+	var got uint64
+	for i, want := range rows[1] {
+		got = 0
+		for b := 0; b < 8; b++ {
+			got |= uint64(data[i*8+b]) << (8 * b)
+		}
+		if got != want {
+			t.Fatalf("row 1, element %d: got %d, want %d", i, got, want)
+		}
+	}
+}
+
+// TestItemSkipWithoutMaterializing proves the laziness claim at the level
+// where it actually holds: Item.List eagerly materializes its payload to
+// split it into sub-items (see Item.List), but reading items directly off
+// the stream via ReadItem+Item.Skip, as a caller recursing through nested
+// lists by hand would, skips a item's payload by discarding it in small
+// chunks, without reading the whole payload into one buffer.
+func TestItemSkipWithoutMaterializing(t *testing.T) {
+
+	var buf bytes.Buffer
+	if _, err := lp.WriteString(&buf, bytes.Repeat([]byte{0xaa}, 1<<20)); err != nil {
+		t.Fatalf("lp.WriteString: %v", err)
+	}
+	if _, err := lp.WriteString(&buf, []byte("second")); err != nil {
+		t.Fatalf("lp.WriteString: %v", err)
+	}
+
+	first, _, err := lp.ReadItem(&buf)
+	if err != nil {
+		t.Fatalf("lp.ReadItem (first): %v", err)
+	}
+	if skipped, err := first.Skip(); err != nil || skipped != first.Len {
+		t.Fatalf("Item.Skip on first item: skipped %d, err %v, want %d, nil", skipped, err, first.Len)
+	}
+
+	second, _, err := lp.ReadItem(&buf)
+	if err != nil {
+		t.Fatalf("lp.ReadItem (second): %v", err)
+	}
+	data, err := second.Bytes()
+	if err != nil {
+		t.Fatalf("Item.Bytes (second): %v", err)
+	}
+	if string(data) != "second" {
+		t.Fatalf("got %q, want %q", data, "second")
+	}
+}
+
+func TestSkipTopLevelItem(t *testing.T) {
+
+	var buf bytes.Buffer
+	if _, err := lp.WriteString(&buf, []byte("first")); err != nil {
+		t.Fatalf("lp.WriteString: %v", err)
+	}
+	if _, err := lp.WriteString(&buf, []byte("second")); err != nil {
+		t.Fatalf("lp.WriteString: %v", err)
+	}
+
+	if _, err := lp.Skip(&buf); err != nil {
+		t.Fatalf("lp.Skip: %v", err)
+	}
+
+	it, _, err := lp.ReadItem(&buf)
+	if err != nil {
+		t.Fatalf("lp.ReadItem: %v", err)
+	}
+	data, err := it.Bytes()
+	if err != nil {
+		t.Fatalf("Item.Bytes: %v", err)
+	}
+	if string(data) != "second" {
+		t.Fatalf("got %q, want %q", data, "second")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/tuneinsight/lattigo/v4/utils/buffer"
+	"github.com/tuneinsight/lattigo/v4/utils/lp"
 )
 
 // Vector is a struct wrapping a doube slice of components of type T.
@@ -167,6 +168,179 @@ func (m *Matrix[T]) UnmarshalBinary(p []byte) (err error) {
 	return
 }
 
+// MarshalTagged encodes the matrix using the schema-tagged, self-describing
+// wire format (see lattigo/utils/buffer/tagged.go), instead of the fixed
+// positional layout used by WriteTo/ReadFrom. Each row is written as a
+// tagged fixed array, so a decoder that does not recognize a later field,
+// or that only needs one row, can skip the rest of the stream without
+// failing to parse it. The header also embeds parametersHash, so an
+// implementation can refuse a stream produced for a different RLWE
+// parameter set before attempting to decode the body.
+//
+// T must be one of the primitive kinds accepted by buffer.WriteTaggedFixedArray.
+// If T is a struct implementing buffer.TaggedMarshaler, use MatrixTagged[T, PT]
+// instead.
+func (m Matrix[T]) MarshalTagged(w buffer.Writer, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = buffer.WriteTaggedHeader(w, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteTaggedHeader: %w", err)
+	}
+	n += inc
+
+	if inc, err = buffer.WriteAsUint64[int64](w, int64(len(m))); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for _, row := range m {
+		if inc, err = buffer.WriteTaggedFixedArray(w, row); err != nil {
+			return n + inc, fmt.Errorf("buffer.WriteTaggedFixedArray: %w", err)
+		}
+		n += inc
+	}
+
+	return n, w.Flush()
+}
+
+// UnmarshalTagged decodes a matrix written by MarshalTagged.
+func (m *Matrix[T]) UnmarshalTagged(r buffer.Reader, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if _, inc, err = buffer.ReadTaggedHeader(r, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadTaggedHeader: %w", err)
+	}
+	n += inc
+
+	var size int64
+	if inc, err = buffer.ReadAsUint64[int64](r, &size); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	*m = make(Matrix[T], size)
+
+	for i := range *m {
+		row, inc2, err := buffer.ReadTaggedFixedArray[T](r)
+		n += inc2
+		if err != nil {
+			return n, fmt.Errorf("buffer.ReadTaggedFixedArray: %w", err)
+		}
+		(*m)[i] = row
+	}
+
+	return n, nil
+}
+
+// TaggedMarshalerPtr constrains a pointer type PT to be both *T and an
+// implementation of buffer.TaggedMarshaler. It is the standard two-type-
+// parameter pattern for giving a value type T tagged (de)serialization
+// methods when only its pointer type has them.
+type TaggedMarshalerPtr[T any] interface {
+	*T
+	buffer.TaggedMarshaler
+}
+
+// MatrixTagged[T, PT] is a Matrix[T] whose component type T's pointer type
+// PT implements buffer.TaggedMarshaler, allowing it to be (de)serialized
+// with MarshalTagged/UnmarshalTagged. It is a conversion type: a Matrix[T]
+// with such a T can be freely cast to MatrixTagged[T, PT] and back.
+type MatrixTagged[T any, PT TaggedMarshalerPtr[T]] Matrix[T]
+
+// MarshalTagged encodes the matrix using the schema-tagged, self-describing
+// wire format (see lattigo/utils/buffer/tagged.go). Each row is written as
+// a tagged variable array of its components' own MarshalTagged output, so a
+// decoder that does not recognize a later field, or that only needs one
+// row, can skip the rest of the stream without failing to parse it.
+func (m MatrixTagged[T, PT]) MarshalTagged(w buffer.Writer, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if inc, err = buffer.WriteTaggedHeader(w, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteTaggedHeader: %w", err)
+	}
+	n += inc
+
+	if inc, err = buffer.WriteAsUint64[int64](w, int64(len(m))); err != nil {
+		return n + inc, fmt.Errorf("buffer.WriteAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	for i := range m {
+
+		row := make([]buffer.TaggedMarshaler, len(m[i]))
+		for j := range m[i] {
+			row[j] = PT(&m[i][j])
+		}
+
+		if inc, err = buffer.WriteTaggedVariableArray(w, row); err != nil {
+			return n + inc, fmt.Errorf("buffer.WriteTaggedVariableArray: %w", err)
+		}
+		n += inc
+	}
+
+	return n, w.Flush()
+}
+
+// UnmarshalTagged decodes a matrix written by MarshalTagged.
+func (m *MatrixTagged[T, PT]) UnmarshalTagged(r buffer.Reader, parametersHash uint64) (n int64, err error) {
+
+	var inc int64
+
+	if _, inc, err = buffer.ReadTaggedHeader(r, parametersHash); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadTaggedHeader: %w", err)
+	}
+	n += inc
+
+	var size int64
+	if inc, err = buffer.ReadAsUint64[int64](r, &size); err != nil {
+		return n + inc, fmt.Errorf("buffer.ReadAsUint64[int64]: %w", err)
+	}
+	n += inc
+
+	*m = make(MatrixTagged[T, PT], size)
+
+	for i := range *m {
+
+		row, inc2, err := buffer.ReadTaggedVariableArray(r, func() buffer.TaggedMarshaler {
+			var t T
+			return PT(&t)
+		})
+		n += inc2
+		if err != nil {
+			return n, fmt.Errorf("buffer.ReadTaggedVariableArray: %w", err)
+		}
+
+		(*m)[i] = make([]T, len(row))
+		for j := range row {
+			(*m)[i][j] = *(row[j].(PT))
+		}
+	}
+
+	return n, nil
+}
+
+// WriteToLP writes the matrix using the length-prefixed recursive encoding
+// (see lattigo/utils/lp), instead of the fixed positional layout used by
+// WriteTo/ReadFrom. The stream is a list of rows, each itself a list (if T
+// is a struct) or a single packed byte string (if T is a primitive), so a
+// receiver can [lp.Skip] any row without decoding it -- useful for lazily
+// loading a single row of a large Matrix.
+//
+// If T is a struct, this method requires that T implements lp.LPMarshaler.
+func (m Matrix[T]) WriteToLP(w io.Writer) (n int64, err error) {
+	return lp.Encode(w, [][]T(m))
+}
+
+// ReadFromLP reads a matrix written by WriteToLP.
+//
+// If T is a struct, this method requires that T implements lp.LPMarshaler.
+func (m *Matrix[T]) ReadFromLP(r io.Reader) (n int64, err error) {
+	return lp.Decode(r, (*[][]T)(m))
+}
+
 // Equal performs a deep equal.
 // If T is a struct, this method requires that T implements Equatable.
 func (m Matrix[T]) Equal(other Matrix[T]) bool {
@@ -0,0 +1,107 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v4/utils/buffer"
+)
+
+// taggedPoint is a minimal buffer.TaggedMarshaler, implemented on the
+// pointer receiver only, used to exercise MatrixTagged[T, PT] with a struct
+// component type.
+type taggedPoint struct {
+	X, Y int64
+}
+
+func (p *taggedPoint) MarshalTagged(w buffer.Writer) (n int64, err error) {
+	sw := buffer.NewTaggedStructWriter()
+	sw.Field("X", func(w buffer.Writer) (int64, error) { return buffer.WriteTaggedScalar(w, p.X) })
+	sw.Field("Y", func(w buffer.Writer) (int64, error) { return buffer.WriteTaggedScalar(w, p.Y) })
+	return sw.WriteTo(w)
+}
+
+func (p *taggedPoint) UnmarshalTagged(r buffer.Reader) (n int64, err error) {
+	return buffer.ReadTaggedStruct(r, map[string]func(r buffer.Reader) (int64, error){
+		"X": func(r buffer.Reader) (int64, error) { return buffer.ReadTaggedScalar(r, &p.X) },
+		"Y": func(r buffer.Reader) (int64, error) { return buffer.ReadTaggedScalar(r, &p.Y) },
+	})
+}
+
+func TestMatrixMarshalTaggedRoundTrip(t *testing.T) {
+
+	m := Matrix[uint64]{
+		{1, 2, 3},
+		{4, 5},
+	}
+
+	w := buffer.NewBufferSize(m.BinarySize() + 64)
+
+	n, err := m.MarshalTagged(w, 0xc0ffee)
+	if err != nil {
+		t.Fatalf("Matrix.MarshalTagged: %v", err)
+	}
+
+	var got Matrix[uint64]
+	if _, err := got.UnmarshalTagged(buffer.NewBuffer(w.Bytes()[:n]), 0xc0ffee); err != nil {
+		t.Fatalf("Matrix.UnmarshalTagged: %v", err)
+	}
+
+	if !got.Equal(m) {
+		t.Fatalf("got %v, want %v", got, m)
+	}
+}
+
+func TestMatrixMarshalTaggedWrongParametersHash(t *testing.T) {
+
+	m := Matrix[uint64]{{1, 2, 3}}
+
+	w := buffer.NewBufferSize(m.BinarySize() + 64)
+
+	n, err := m.MarshalTagged(w, 0xc0ffee)
+	if err != nil {
+		t.Fatalf("Matrix.MarshalTagged: %v", err)
+	}
+
+	var got Matrix[uint64]
+	if _, err := got.UnmarshalTagged(buffer.NewBuffer(w.Bytes()[:n]), 0xbadbad); err == nil {
+		t.Fatalf("UnmarshalTagged with a mismatching parametersHash: got nil error, want non-nil")
+	}
+}
+
+// TestMatrixTaggedRoundTrip proves that MatrixTagged[T, PT] round-trips a
+// struct component type whose buffer.TaggedMarshaler methods are only
+// defined on the pointer receiver.
+func TestMatrixTaggedRoundTrip(t *testing.T) {
+
+	m := MatrixTagged[taggedPoint, *taggedPoint]{
+		{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		{{X: 5, Y: 6}},
+	}
+
+	w := buffer.NewBufferSize(1 << 12)
+
+	n, err := m.MarshalTagged(w, 42)
+	if err != nil {
+		t.Fatalf("MatrixTagged.MarshalTagged: %v", err)
+	}
+
+	var got MatrixTagged[taggedPoint, *taggedPoint]
+	if _, err := got.UnmarshalTagged(buffer.NewBuffer(w.Bytes()[:n]), 42); err != nil {
+		t.Fatalf("MatrixTagged.UnmarshalTagged: %v", err)
+	}
+
+	if len(got) != len(m) {
+		t.Fatalf("got %d rows, want %d", len(got), len(m))
+	}
+
+	for i := range m {
+		if len(got[i]) != len(m[i]) {
+			t.Fatalf("row %d: got %d components, want %d", i, len(got[i]), len(m[i]))
+		}
+		for j := range m[i] {
+			if got[i][j] != m[i][j] {
+				t.Fatalf("row %d, component %d: got %+v, want %+v", i, j, got[i][j], m[i][j])
+			}
+		}
+	}
+}